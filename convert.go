@@ -27,6 +27,9 @@
 // 		<number name="Latitude">51.1789</number>
 // 	</object>
 // </object>`
+//
+// The mapping is lossless and symmetric: ReverseConverter and ConvertXML read
+// this tag vocabulary back and reconstruct the original JSON.
 package json2xml
 
 import (
@@ -39,9 +42,10 @@ import (
 
 // Errors.
 var (
-	ErrInvalidKey   = errors.New("invalid key type")
-	ErrUnknownToken = errors.New("unknown token type")
-	ErrInvalidToken = errors.New("invalid token")
+	ErrInvalidKey     = errors.New("invalid key type")
+	ErrUnknownToken   = errors.New("unknown token type")
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrInvalidXMLName = errors.New("invalid xml name")
 )
 
 var (
@@ -62,6 +66,19 @@ const (
 
 var ttypeNames = [...]string{"object", "array", "boolean", "number", "string", "null"}
 
+// frame tracks an open element on the Converter's type stack: its JSON type,
+// and the XML local name it was actually opened with (which may differ from
+// ttypeNames[typ] when KeyAsElement is in use).
+type frame struct {
+	typ  ttype
+	name string
+
+	// seg is the JSON path segment used to reach this frame: the object key
+	// it was a member of, "[]" if it was an array element, or "" for the
+	// root value. It is used to evaluate Include and Exclude.
+	seg string
+}
+
 // JSONDecoder represents a type that gives out JSON tokens, usually
 // implemented by *json.Decoder
 // It is encouraged for implementers of this interface to output numbers using
@@ -81,21 +98,132 @@ type XMLEncoder interface {
 // Converter represents the ongoing conversion from JSON to XML.
 type Converter struct {
 	decoder JSONDecoder
-	types   []ttype
+	types   []frame
 	data    *string
+
+	peeked    bool
+	peekToken xml.Token
+	peekErr   error
+
+	// NameMapper, if set, is called with every JSON object key before it is
+	// written to XML, and its result used instead. It can be used to sanitize
+	// keys that are not valid XML names; see SanitizeXMLName.
+	NameMapper func(jsonKey string) (xmlName string, err error)
+
+	// KeyAsElement makes the JSON key of an object member become the local
+	// name of its XML element, instead of a "name" attribute on an element
+	// named after the JSON type. For example "Location" becomes
+	// <Location>...</Location> instead of <object name="Location">...
+	// </object>. This is the shape most XSLT and XPath consumers expect, but
+	// it is not read back by ReverseConverter, which relies on the type name.
+	KeyAsElement bool
+
+	// InferTypes makes the Converter emit <number>, <boolean> or <null>
+	// instead of <string> for a JSON string value that looks like a number,
+	// boolean, or null. This is useful for sources that quote everything,
+	// such as CSV-derived JSON or some REST APIs.
+	InferTypes bool
+
+	// Include, if non-empty, restricts conversion to JSON values whose path
+	// matches one of these patterns, plus whatever ancestors and descendants
+	// that implies. Exclude drops values (and their descendants) whose path
+	// matches one of these patterns, taking precedence over Include. A
+	// dropped value is drained from the JSON decoder stream entirely: it is
+	// never turned into XML tokens.
+	//
+	// A path is a slash-delimited sequence of JSON object keys leading to
+	// the value, using "*" to match any single key or array element, and
+	// "[]" to match only an array element. For example "foo/items/*/id"
+	// matches the "id" member of every element of the "items" array nested
+	// under the top-level "foo" key.
+	Include []string
+	Exclude []string
+
+	includeSegs, excludeSegs [][]string
+	filtersCompiled          bool
 }
 
 // NewConverter provides a JSON converter that implements the xml.TokenReader
 // interface.
-func NewConverter(j JSONDecoder) *Converter {
-	return &Converter{
+func NewConverter(j JSONDecoder, opts ...Option) *Converter {
+	c := &Converter{
 		decoder: j,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Option configures a Converter constructed by NewConverter.
+type Option func(*Converter)
+
+// WithNameMapper sets the Converter's NameMapper.
+func WithNameMapper(m func(jsonKey string) (xmlName string, err error)) Option {
+	return func(c *Converter) {
+		c.NameMapper = m
+	}
+}
+
+// WithKeyAsElement sets the Converter's KeyAsElement mode.
+func WithKeyAsElement(enabled bool) Option {
+	return func(c *Converter) {
+		c.KeyAsElement = enabled
+	}
+}
+
+// WithInferTypes sets the Converter's InferTypes mode.
+func WithInferTypes(enabled bool) Option {
+	return func(c *Converter) {
+		c.InferTypes = enabled
+	}
+}
+
+// WithInclude sets the Converter's Include paths.
+func WithInclude(paths ...string) Option {
+	return func(c *Converter) {
+		c.Include = paths
+	}
+}
+
+// WithExclude sets the Converter's Exclude paths.
+func WithExclude(paths ...string) Option {
+	return func(c *Converter) {
+		c.Exclude = paths
+	}
 }
 
 // Token gets a xml.Token from the Converter, as per the xml.TokenReader
 // interface.
 func (c *Converter) Token() (xml.Token, error) {
+	if c.peeked {
+		token, err := c.peekToken, c.peekErr
+		c.peeked = false
+		c.peekToken = nil
+		c.peekErr = nil
+
+		return token, err
+	}
+
+	return c.nextToken()
+}
+
+// Peek returns the next xml.Token without consuming it. The following call
+// to Token will return the same token and error, and advance the Converter
+// as usual. Repeated calls to Peek without an intervening Token return the
+// same cached result.
+func (c *Converter) Peek() (xml.Token, error) {
+	if !c.peeked {
+		c.peekToken, c.peekErr = c.nextToken()
+		c.peeked = true
+	}
+
+	return c.peekToken, c.peekErr
+}
+
+func (c *Converter) nextToken() (xml.Token, error) {
 	if c.data != nil {
 		token := xml.CharData(*c.data)
 		c.data = nil
@@ -104,123 +232,197 @@ func (c *Converter) Token() (xml.Token, error) {
 	}
 
 	if len(c.types) > 0 {
-		switch c.types[len(c.types)-1] {
+		switch c.types[len(c.types)-1].typ {
 		case typObject, typArray:
 		default:
 			return c.outputEnd(), nil
 		}
 	}
 
-	var keyName *string
+	filtering := len(c.Include) > 0 || len(c.Exclude) > 0
 
-	token, err := c.decoder.Token()
-	if err != nil {
-		return nil, err
-	}
-
-	if len(c.types) > 0 && c.types[len(c.types)-1] == typObject && token != json.Delim('}') {
-		tokenStr, ok := token.(string)
-		if !ok {
-			return nil, ErrInvalidKey
-		}
+	for {
+		inObject := len(c.types) > 0 && c.types[len(c.types)-1].typ == typObject
+		inArray := len(c.types) > 0 && c.types[len(c.types)-1].typ == typArray
 
-		keyName = &tokenStr
+		var keyName *string
 
-		token, err = c.decoder.Token()
+		token, err := c.decoder.Token()
 		if err != nil {
 			return nil, err
 		}
-	}
 
-	switch token := token.(type) {
-	case json.Delim:
-		switch token {
-		case '{':
-			return c.outputStart(typObject, keyName), nil
-		case '[':
-			return c.outputStart(typArray, keyName), nil
-		case '}':
-			if len(c.types) == 0 || c.types[len(c.types)-1] != typObject {
-				return nil, ErrInvalidToken
+		if inObject && token != json.Delim('}') {
+			tokenStr, ok := token.(string)
+			if !ok {
+				return nil, ErrInvalidKey
 			}
 
-			return c.outputEnd(), nil
-		case ']':
-			if len(c.types) == 0 || c.types[len(c.types)-1] != typArray {
-				return nil, ErrInvalidToken
+			keyName = &tokenStr
+
+			token, err = c.decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if d, ok := token.(json.Delim); ok {
+			switch d {
+			case '}':
+				if !inObject {
+					return nil, ErrInvalidToken
+				}
+
+				return c.outputEnd(), nil
+			case ']':
+				if !inArray {
+					return nil, ErrInvalidToken
+				}
+
+				return c.outputEnd(), nil
 			}
+		}
 
-			return c.outputEnd(), nil
-		default:
-			return nil, ErrUnknownToken
+		var seg string
+
+		switch {
+		case keyName != nil:
+			seg = *keyName
+		case inArray:
+			seg = "[]"
 		}
-	case bool:
-		if token {
-			return c.outputType(typBool, &cTrue, keyName), nil
+
+		_, isContainer := token.(json.Delim)
+		isRoot := len(c.types) == 0
+
+		if filtering && !c.keepPath(c.currentPath(seg), isContainer, isRoot) {
+			if err := c.drainValue(token); err != nil {
+				return nil, err
+			}
+
+			continue
 		}
 
-		return c.outputType(typBool, &cFalse, keyName), nil
-	case float64:
-		number := strconv.FormatFloat(token, 'f', -1, 64)
-		return c.outputType(typNumber, &number, keyName), nil
-	case json.Number:
-		return c.outputType(typNumber, (*string)(&token), keyName), nil
-	case string:
-		return c.outputType(typString, &token, keyName), nil
-	case nil:
-		return c.outputType(typNull, nil, keyName), nil
-	default:
-		return nil, ErrUnknownToken
+		switch token := token.(type) {
+		case json.Delim:
+			switch token {
+			case '{':
+				return c.outputStart(typObject, keyName, seg)
+			case '[':
+				return c.outputStart(typArray, keyName, seg)
+			default:
+				return nil, ErrUnknownToken
+			}
+		case bool:
+			if token {
+				return c.outputType(typBool, &cTrue, keyName, seg)
+			}
+
+			return c.outputType(typBool, &cFalse, keyName, seg)
+		case float64:
+			number := strconv.FormatFloat(token, 'f', -1, 64)
+			return c.outputType(typNumber, &number, keyName, seg)
+		case json.Number:
+			return c.outputType(typNumber, (*string)(&token), keyName, seg)
+		case string:
+			if c.InferTypes {
+				if typ, data := inferType(token); typ != typString {
+					return c.outputType(typ, data, keyName, seg)
+				}
+			}
+
+			return c.outputType(typString, &token, keyName, seg)
+		case nil:
+			return c.outputType(typNull, nil, keyName, seg)
+		default:
+			return nil, ErrUnknownToken
+		}
 	}
 }
 
-func (c *Converter) outputType(typ ttype, data *string, keyName *string) xml.Token {
+func (c *Converter) outputType(typ ttype, data *string, keyName *string, seg string) (xml.Token, error) {
 	c.data = data
 
-	return c.outputStart(typ, keyName)
+	return c.outputStart(typ, keyName, seg)
 }
 
-func (c *Converter) outputStart(typ ttype, keyName *string) xml.Token {
-	c.types = append(c.types, typ)
-
+func (c *Converter) outputStart(typ ttype, keyName *string, seg string) (xml.Token, error) {
+	name := ttypeNames[typ]
 	var attr []xml.Attr
 
 	if keyName != nil {
-		attr = []xml.Attr{
-			{
-				Name: xml.Name{
-					Local: "name",
+		mapped := *keyName
+
+		if c.NameMapper != nil {
+			var err error
+
+			mapped, err = c.NameMapper(mapped)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if c.KeyAsElement {
+			if !isValidXMLName(mapped) {
+				return nil, ErrInvalidXMLName
+			}
+
+			name = mapped
+		} else {
+			attr = []xml.Attr{
+				{
+					Name: xml.Name{
+						Local: "name",
+					},
+					Value: mapped,
 				},
-				Value: *keyName,
-			},
+			}
 		}
 	}
 
+	c.types = append(c.types, frame{typ: typ, name: name, seg: seg})
+
 	return xml.StartElement{
 		Name: xml.Name{
-			Local: ttypeNames[typ],
+			Local: name,
 		},
 		Attr: attr,
-	}
+	}, nil
 }
 
 func (c *Converter) outputEnd() xml.Token {
-	typ := c.types[len(c.types)-1]
+	f := c.types[len(c.types)-1]
 	c.types = c.types[:len(c.types)-1]
 
 	return xml.EndElement{
 		Name: xml.Name{
-			Local: ttypeNames[typ],
+			Local: f.name,
 		},
 	}
 }
 
-// Convert converts JSON and sends it to the given XML encoder.
-func Convert(j JSONDecoder, x XMLEncoder) error {
-	c := Converter{
-		decoder: j,
+// inferType decides which XML type a JSON string value should be emitted as
+// when InferTypes is set: a boolean or null literal, a valid JSON number, or
+// otherwise typString, in which case data is unused.
+func inferType(s string) (typ ttype, data *string) {
+	switch s {
+	case "null":
+		return typNull, nil
+	case cTrue, cFalse:
+		return typBool, &s
+	}
+
+	if numberPattern.MatchString(s) {
+		return typNumber, &s
 	}
 
+	return typString, nil
+}
+
+// Convert converts JSON and sends it to the given XML encoder.
+func Convert(j JSONDecoder, x XMLEncoder, opts ...Option) error {
+	c := NewConverter(j, opts...)
+
 	for {
 		tk, err := c.Token()
 		if err != nil {
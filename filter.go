@@ -0,0 +1,151 @@
+package json2xml
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// currentPath builds the JSON path of the value about to be read, which is
+// made of the seg of every open frame plus seg itself, for Include/Exclude
+// matching.
+func (c *Converter) currentPath(seg string) []string {
+	path := make([]string, 0, len(c.types)+1)
+
+	for _, f := range c.types {
+		if f.seg != "" {
+			path = append(path, f.seg)
+		}
+	}
+
+	if seg != "" {
+		path = append(path, seg)
+	}
+
+	return path
+}
+
+// keepPath reports whether the value at path should be converted to XML, or
+// dropped because of Exclude or Include. isContainer distinguishes a value
+// that can have deeper descendants (object or array) from a scalar, which
+// can only ever satisfy an Include pattern as an exact match, never as an
+// ancestor of one. isRoot exempts the top-level JSON value from Include:
+// Include paths name members to keep, and a document's root has no member
+// name of its own to match against.
+func (c *Converter) keepPath(path []string, isContainer, isRoot bool) bool {
+	c.compileFilters()
+
+	for _, ex := range c.excludeSegs {
+		if len(path) == len(ex) && pathCompatible(ex, path) {
+			return false
+		}
+	}
+
+	if isRoot || len(c.includeSegs) == 0 {
+		return true
+	}
+
+	for _, inc := range c.includeSegs {
+		if len(path) > len(inc) {
+			// A descendant of a value matched by inc is kept too.
+			if pathCompatible(inc, path[:len(inc)]) {
+				return true
+			}
+
+			continue
+		}
+
+		if !pathCompatible(inc, path) {
+			continue
+		}
+
+		if isContainer || len(path) == len(inc) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Converter) compileFilters() {
+	if c.filtersCompiled {
+		return
+	}
+
+	for _, p := range c.Include {
+		c.includeSegs = append(c.includeSegs, splitPath(p))
+	}
+
+	for _, p := range c.Exclude {
+		c.excludeSegs = append(c.excludeSegs, splitPath(p))
+	}
+
+	c.filtersCompiled = true
+}
+
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+
+	return strings.Split(p, "/")
+}
+
+// pathCompatible reports whether path could lead to, or is, a value matched
+// by pattern: every segment of path is compatible with the pattern segment
+// at the same position. path may be shorter than pattern, meaning it is an
+// ancestor that must still be descended into to find out whether it
+// matches.
+func pathCompatible(pattern, path []string) bool {
+	if len(path) > len(pattern) {
+		return false
+	}
+
+	for i, seg := range path {
+		if !segMatches(pattern[i], seg) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func segMatches(pattern, seg string) bool {
+	switch pattern {
+	case "*":
+		return true
+	case "[]":
+		return seg == "[]"
+	default:
+		return pattern == seg
+	}
+}
+
+// drainValue consumes the remainder of the JSON value represented by token,
+// which has already been read from the decoder, without producing any XML
+// tokens for it. Scalar tokens are already fully consumed; object and array
+// values are drained by tracking "{"/"[" depth until the matching close.
+func (c *Converter) drainValue(token json.Token) error {
+	d, ok := token.(json.Delim)
+	if !ok || (d != '{' && d != '[') {
+		return nil
+	}
+
+	for depth := 1; depth > 0; {
+		tok, err := c.decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		if dd, ok := tok.(json.Delim); ok {
+			switch dd {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+	}
+
+	return nil
+}
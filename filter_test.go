@@ -0,0 +1,89 @@
+package json2xml
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+)
+
+func TestExcludeDrainsMatchedSubtree(t *testing.T) {
+	in := `{"foo":{"items":[{"id":1,"name":"a"},{"id":2,"name":"b"}],"keep":"yes"}}`
+
+	got, err := convertWith(t, in, WithExclude("foo/items/*/id"))
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	want := `<object><object name="foo"><array name="items">` +
+		`<object><string name="name">a</string></object>` +
+		`<object><string name="name">b</string></object>` +
+		`</array><string name="keep">yes</string></object></object>`
+
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestIncludeKeepsOnlyMatchedPaths(t *testing.T) {
+	in := `{"foo":{"items":[{"id":1,"name":"a"},{"id":2,"name":"b"}],"keep":"yes"}}`
+
+	got, err := convertWith(t, in, WithInclude("foo/items/*/id"))
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	want := `<object><object name="foo"><array name="items">` +
+		`<object><number name="id">1</number></object>` +
+		`<object><number name="id">2</number></object>` +
+		`</array></object></object>`
+
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestIncludeDoesNotDropScalarRoot(t *testing.T) {
+	got, err := convertWith(t, `42`, WithInclude("foo"))
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	want := `<number>42</number>`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestIncludeDropsScalarThatCannotMatchDeeperPattern(t *testing.T) {
+	got, err := convertWith(t, `{"a":5,"c":1}`, WithInclude("a/b"))
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	want := `<object></object>`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestExcludeAppliesToWholeArrayElement(t *testing.T) {
+	dec := json.NewDecoder(bytes.NewBufferString(`{"items":[{"id":1},{"id":2}]}`))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+
+	if err := Convert(dec, enc, WithExclude("items/[]")); err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := `<object><array name="items"></array></object>`
+	if got := buf.String(); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,67 @@
+package json2xml
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+)
+
+func TestInferTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"number", `{"n":"42"}`, `<object><number name="n">42</number></object>`},
+		{"negative float", `{"n":"-1.5e3"}`, `<object><number name="n">-1.5e3</number></object>`},
+		{"true", `{"b":"true"}`, `<object><boolean name="b">true</boolean></object>`},
+		{"false", `{"b":"false"}`, `<object><boolean name="b">false</boolean></object>`},
+		{"null", `{"z":"null"}`, `<object><null name="z"></null></object>`},
+		{"not a number stays a string", `{"s":"007"}`, `<object><string name="s">007</string></object>`},
+		{"ordinary string", `{"s":"hello"}`, `<object><string name="s">hello</string></object>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := json.NewDecoder(bytes.NewBufferString(tt.in))
+			dec.UseNumber()
+
+			var buf bytes.Buffer
+			enc := xml.NewEncoder(&buf)
+
+			if err := Convert(dec, enc, WithInferTypes(true)); err != nil {
+				t.Fatalf("Convert: %v", err)
+			}
+
+			if err := enc.Flush(); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInferTypesOffKeepsStrings(t *testing.T) {
+	dec := json.NewDecoder(bytes.NewBufferString(`{"n":"42"}`))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+
+	if err := Convert(dec, enc); err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := `<object><string name="n">42</string></object>`
+	if got := buf.String(); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
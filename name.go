@@ -0,0 +1,123 @@
+package json2xml
+
+import "strings"
+
+// SanitizeXMLName is a NameMapper that makes jsonKey safe to use as an XML
+// element or attribute name, per the XML 1.0 Name production: runes that are
+// not valid anywhere in a Name are replaced with "_". A leading digit (and
+// any other rune that is a valid NameChar but not a valid NameStartChar) is
+// kept, but prefixed with "_", since it is invalid only in the first
+// position.
+//
+// It never returns an error.
+func SanitizeXMLName(jsonKey string) (string, error) {
+	if jsonKey == "" {
+		return "_", nil
+	}
+
+	var b strings.Builder
+
+	for i, r := range jsonKey {
+		switch {
+		case isXMLNameStartChar(r):
+			b.WriteRune(r)
+		case i == 0 && isXMLNameChar(r):
+			b.WriteByte('_')
+			b.WriteRune(r)
+		case i > 0 && isXMLNameChar(r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	return b.String(), nil
+}
+
+// isValidXMLName reports whether s is a legal XML 1.0 Name: non-empty, with
+// a valid NameStartChar followed by zero or more valid NameChars.
+func isValidXMLName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, r := range s {
+		if i == 0 {
+			if !isXMLNameStartChar(r) {
+				return false
+			}
+
+			continue
+		}
+
+		if !isXMLNameChar(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isXMLNameStartChar reports whether r may be the first character of an XML
+// Name, per https://www.w3.org/TR/xml/#NT-NameStartChar.
+func isXMLNameStartChar(r rune) bool {
+	switch {
+	case r == ':' || r == '_':
+		return true
+	case r >= 'A' && r <= 'Z':
+		return true
+	case r >= 'a' && r <= 'z':
+		return true
+	case r >= 0xC0 && r <= 0xD6:
+		return true
+	case r >= 0xD8 && r <= 0xF6:
+		return true
+	case r >= 0xF8 && r <= 0x2FF:
+		return true
+	case r >= 0x370 && r <= 0x37D:
+		return true
+	case r >= 0x37F && r <= 0x1FFF:
+		return true
+	case r >= 0x200C && r <= 0x200D:
+		return true
+	case r >= 0x2070 && r <= 0x218F:
+		return true
+	case r >= 0x2C00 && r <= 0x2FEF:
+		return true
+	case r >= 0x3001 && r <= 0xD7FF:
+		return true
+	case r >= 0xF900 && r <= 0xFDCF:
+		return true
+	case r >= 0xFDF0 && r <= 0xFFFD:
+		return true
+	case r >= 0x10000 && r <= 0xEFFFF:
+		return true
+	default:
+		return false
+	}
+}
+
+// isXMLNameChar reports whether r may appear after the first character of an
+// XML Name, per https://www.w3.org/TR/xml/#NT-NameChar.
+func isXMLNameChar(r rune) bool {
+	switch {
+	case isXMLNameStartChar(r):
+		return true
+	case r == '-' || r == '.':
+		return true
+	case isDigit(r):
+		return true
+	case r == 0xB7:
+		return true
+	case r >= 0x0300 && r <= 0x036F:
+		return true
+	case r >= 0x203F && r <= 0x2040:
+		return true
+	default:
+		return false
+	}
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
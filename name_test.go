@@ -0,0 +1,37 @@
+package json2xml
+
+import "testing"
+
+func TestSanitizeXMLName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Longitude", "Longitude"},
+		{"my key", "my_key"},
+		{"1foo", "_1foo"},
+		{"2foo", "_2foo"},
+		{"", "_"},
+		{"a<b", "a_b"},
+	}
+
+	for _, tt := range tests {
+		got, err := SanitizeXMLName(tt.in)
+		if err != nil {
+			t.Errorf("SanitizeXMLName(%q): unexpected error %v", tt.in, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("SanitizeXMLName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeXMLNameLeadingDigitsDontCollide(t *testing.T) {
+	a, _ := SanitizeXMLName("1foo")
+	b, _ := SanitizeXMLName("2foo")
+
+	if a == b {
+		t.Fatalf("SanitizeXMLName(%q) and SanitizeXMLName(%q) both produced %q", "1foo", "2foo", a)
+	}
+}
@@ -0,0 +1,72 @@
+package json2xml
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"testing"
+)
+
+func convertWith(t *testing.T, in string, opts ...Option) (string, error) {
+	t.Helper()
+
+	dec := json.NewDecoder(bytes.NewBufferString(in))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+
+	if err := Convert(dec, enc, opts...); err != nil {
+		return "", err
+	}
+
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	return buf.String(), nil
+}
+
+func TestKeyAsElement(t *testing.T) {
+	got, err := convertWith(t, `{"Location":{"Longitude":1}}`, WithKeyAsElement(true))
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	want := `<object><Location><Longitude>1</Longitude></Location></object>`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestKeyAsElementRejectsInvalidXMLNameByDefault(t *testing.T) {
+	_, err := convertWith(t, `{"my key":1}`, WithKeyAsElement(true))
+	if err != ErrInvalidXMLName {
+		t.Fatalf("got err %v, want %v", err, ErrInvalidXMLName)
+	}
+}
+
+func TestKeyAsElementWithSanitizeXMLName(t *testing.T) {
+	got, err := convertWith(t, `{"my key":1}`, WithKeyAsElement(true), WithNameMapper(SanitizeXMLName))
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	want := `<object><my_key>1</my_key></object>`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestNameMapperErrorPropagates(t *testing.T) {
+	boom := errors.New("boom")
+
+	_, err := convertWith(t, `{"a":1}`, WithNameMapper(func(string) (string, error) {
+		return "", boom
+	}))
+
+	if err != boom {
+		t.Fatalf("got err %v, want %v", err, boom)
+	}
+}
@@ -0,0 +1,97 @@
+package json2xml
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestPeekReturnsSameTokenUntilConsumed(t *testing.T) {
+	dec := json.NewDecoder(bytes.NewBufferString(`{"n":1}`))
+	dec.UseNumber()
+	c := NewConverter(dec)
+
+	peeked1, err := c.Peek()
+	if err != nil {
+		t.Fatalf("Peek: %v", err)
+	}
+
+	peeked2, err := c.Peek()
+	if err != nil {
+		t.Fatalf("Peek: %v", err)
+	}
+
+	if !reflect.DeepEqual(peeked1, peeked2) {
+		t.Fatalf("repeated Peek returned different tokens: %#v != %#v", peeked1, peeked2)
+	}
+
+	got, err := c.Token()
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, peeked1) {
+		t.Fatalf("Token after Peek returned %#v, want %#v", got, peeked1)
+	}
+}
+
+func TestPeekPreservesScalarTwoTokenExpansion(t *testing.T) {
+	dec := json.NewDecoder(bytes.NewBufferString(`{"n":1}`))
+	dec.UseNumber()
+	c := NewConverter(dec)
+
+	// object start, then the key's number start element.
+	if _, err := c.Token(); err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+
+	startPeek, err := c.Peek()
+	if err != nil {
+		t.Fatalf("Peek: %v", err)
+	}
+
+	if _, ok := startPeek.(xml.StartElement); !ok {
+		t.Fatalf("peeked token is %#v, want xml.StartElement", startPeek)
+	}
+
+	start, err := c.Token()
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+
+	if !reflect.DeepEqual(start, startPeek) {
+		t.Fatalf("Token after Peek returned %#v, want %#v", start, startPeek)
+	}
+
+	charData, err := c.Token()
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+
+	if cd, ok := charData.(xml.CharData); !ok || string(cd) != "1" {
+		t.Fatalf("got %#v, want CharData(1)", charData)
+	}
+}
+
+func TestPeekCachesError(t *testing.T) {
+	dec := json.NewDecoder(bytes.NewBufferString(`not json`))
+	c := NewConverter(dec)
+
+	_, err1 := c.Peek()
+	_, err2 := c.Peek()
+
+	if err1 == nil || err2 == nil {
+		t.Fatal("expected an error from Peek")
+	}
+
+	if err1.Error() != err2.Error() {
+		t.Fatalf("repeated Peek returned different errors: %v != %v", err1, err2)
+	}
+
+	_, err3 := c.Token()
+	if err3.Error() != err1.Error() {
+		t.Fatalf("Token after Peek returned different error: %v != %v", err3, err1)
+	}
+}
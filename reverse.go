@@ -0,0 +1,242 @@
+package json2xml
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"regexp"
+)
+
+// Errors returned while reversing an XML token stream produced by this
+// package back into JSON.
+var (
+	ErrMissingKey     = errors.New("missing name attribute")
+	ErrInvalidNumber  = errors.New("invalid number")
+	ErrInvalidBoolean = errors.New("invalid boolean")
+	ErrInvalidNull    = errors.New("invalid null")
+	ErrUnknownElement = errors.New("unknown element")
+	ErrUnexpectedXML  = errors.New("unexpected xml token")
+)
+
+// numberPattern matches the grammar of a JSON number, per the encoding/json
+// and RFC 8259 definitions.
+var numberPattern = regexp.MustCompile(`^-?(?:0|[1-9]\d*)(?:\.\d+)?(?:[eE][+-]?\d+)?$`)
+
+// JSONEncoder represents a type that takes a decoded value and writes it out
+// as JSON, usually implemented by *json.Encoder.
+type JSONEncoder interface {
+	Encode(v interface{}) error
+}
+
+// ReverseConverter represents the ongoing conversion from XML, in the tag
+// vocabulary documented on this package, back to JSON.
+type ReverseConverter struct {
+	decoder xml.TokenReader
+}
+
+// NewReverseConverter provides a converter that reads XML tokens produced by
+// Converter and reconstructs the original JSON value.
+func NewReverseConverter(x xml.TokenReader) *ReverseConverter {
+	return &ReverseConverter{
+		decoder: x,
+	}
+}
+
+// Value reads the next complete JSON value from the XML token stream.
+func (r *ReverseConverter) Value() (interface{}, error) {
+	tok, err := r.nextNonSpace()
+	if err != nil {
+		return nil, err
+	}
+
+	start, ok := tok.(xml.StartElement)
+	if !ok {
+		return nil, ErrUnexpectedXML
+	}
+
+	return r.parseElement(start)
+}
+
+func (r *ReverseConverter) next() (xml.Token, error) {
+	return r.decoder.Token()
+}
+
+// nextNonSpace returns the next token, skipping over whitespace-only
+// character data such as indentation inserted by an xml.Encoder.
+func (r *ReverseConverter) nextNonSpace() (xml.Token, error) {
+	for {
+		tok, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+
+		if cd, ok := tok.(xml.CharData); ok && len(bytes.TrimSpace(cd)) == 0 {
+			continue
+		}
+
+		return tok, nil
+	}
+}
+
+func (r *ReverseConverter) parseElement(start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case ttypeNames[typObject]:
+		return r.parseObject()
+	case ttypeNames[typArray]:
+		return r.parseArray()
+	case ttypeNames[typString]:
+		return r.parseScalar(start.Name.Local, typString)
+	case ttypeNames[typNumber]:
+		return r.parseScalar(start.Name.Local, typNumber)
+	case ttypeNames[typBool]:
+		return r.parseScalar(start.Name.Local, typBool)
+	case ttypeNames[typNull]:
+		return r.parseScalar(start.Name.Local, typNull)
+	default:
+		return nil, ErrUnknownElement
+	}
+}
+
+func (r *ReverseConverter) parseObject() (map[string]interface{}, error) {
+	obj := make(map[string]interface{})
+
+	for {
+		tok, err := r.nextNonSpace()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.EndElement:
+			if t.Name.Local != ttypeNames[typObject] {
+				return nil, ErrUnexpectedXML
+			}
+
+			return obj, nil
+		case xml.StartElement:
+			key, err := attrValue(t, "name")
+			if err != nil {
+				return nil, err
+			}
+
+			val, err := r.parseElement(t)
+			if err != nil {
+				return nil, err
+			}
+
+			obj[key] = val
+		default:
+			return nil, ErrUnexpectedXML
+		}
+	}
+}
+
+func (r *ReverseConverter) parseArray() ([]interface{}, error) {
+	arr := []interface{}{}
+
+	for {
+		tok, err := r.nextNonSpace()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.EndElement:
+			if t.Name.Local != ttypeNames[typArray] {
+				return nil, ErrUnexpectedXML
+			}
+
+			return arr, nil
+		case xml.StartElement:
+			val, err := r.parseElement(t)
+			if err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, val)
+		default:
+			return nil, ErrUnexpectedXML
+		}
+	}
+}
+
+func (r *ReverseConverter) parseScalar(name string, typ ttype) (interface{}, error) {
+	tok, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		data    string
+		hasData bool
+	)
+
+	if cd, ok := tok.(xml.CharData); ok {
+		data = string(cd)
+		hasData = true
+
+		tok, err = r.next()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	end, ok := tok.(xml.EndElement)
+	if !ok || end.Name.Local != name {
+		return nil, ErrUnexpectedXML
+	}
+
+	switch typ {
+	case typString:
+		return data, nil
+	case typNumber:
+		if !hasData || !numberPattern.MatchString(data) {
+			return nil, ErrInvalidNumber
+		}
+
+		return json.Number(data), nil
+	case typBool:
+		switch {
+		case !hasData:
+			return nil, ErrInvalidBoolean
+		case data == cTrue:
+			return true, nil
+		case data == cFalse:
+			return false, nil
+		default:
+			return nil, ErrInvalidBoolean
+		}
+	case typNull:
+		if hasData {
+			return nil, ErrInvalidNull
+		}
+
+		return nil, nil
+	default:
+		return nil, ErrUnknownElement
+	}
+}
+
+func attrValue(start xml.StartElement, name string) (string, error) {
+	for _, a := range start.Attr {
+		if a.Name.Local == name {
+			return a.Value, nil
+		}
+	}
+
+	return "", ErrMissingKey
+}
+
+// ConvertXML converts XML produced by this package, read from x, back to
+// JSON and sends it to the given JSON encoder.
+func ConvertXML(x xml.TokenReader, j JSONEncoder) error {
+	r := NewReverseConverter(x)
+
+	v, err := r.Value()
+	if err != nil {
+		return err
+	}
+
+	return j.Encode(v)
+}
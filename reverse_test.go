@@ -0,0 +1,92 @@
+package json2xml
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConvertXMLRoundTrip(t *testing.T) {
+	in := `{"Location":{"Longitude":-1.8262,"Latitude":51.1789},"tags":["a","b"],"active":true,"note":null}`
+
+	dec := json.NewDecoder(bytes.NewBufferString(in))
+	dec.UseNumber()
+
+	var xmlBuf bytes.Buffer
+	enc := xml.NewEncoder(&xmlBuf)
+
+	if err := Convert(dec, enc); err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	var jsonBuf bytes.Buffer
+	jEnc := json.NewEncoder(&jsonBuf)
+
+	if err := ConvertXML(xml.NewDecoder(&xmlBuf), jEnc); err != nil {
+		t.Fatalf("ConvertXML: %v", err)
+	}
+
+	var want, got interface{}
+	if err := json.Unmarshal([]byte(in), &want); err != nil {
+		t.Fatalf("Unmarshal want: %v", err)
+	}
+
+	if err := json.Unmarshal(jsonBuf.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal got: %v", err)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("round trip mismatch: got %#v, want %#v", got, want)
+	}
+}
+
+func value(t *testing.T, xmlSrc string) (interface{}, error) {
+	t.Helper()
+
+	r := NewReverseConverter(xml.NewDecoder(bytes.NewBufferString(xmlSrc)))
+
+	return r.Value()
+}
+
+func TestValueErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+		want error
+	}{
+		{"invalid number", `<number>1.2.3</number>`, ErrInvalidNumber},
+		{"empty number", `<number></number>`, ErrInvalidNumber},
+		{"invalid boolean", `<boolean>yes</boolean>`, ErrInvalidBoolean},
+		{"null with chardata", `<null>x</null>`, ErrInvalidNull},
+		{"missing name in object", `<object><string>a</string></object>`, ErrMissingKey},
+		{"unknown element", `<widget/>`, ErrUnknownElement},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := value(t, tt.xml)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got err %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValueObjectHonorsNameAttribute(t *testing.T) {
+	got, err := value(t, `<object><number name="Longitude">-1.8262</number></object>`)
+	if err != nil {
+		t.Fatalf("Value: %v", err)
+	}
+
+	want := map[string]interface{}{"Longitude": json.Number("-1.8262")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
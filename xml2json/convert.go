@@ -0,0 +1,281 @@
+// Package xml2json converts arbitrary XML to JSON, driven by an
+// xml.TokenReader so that it works on streams rather than whole documents.
+//
+// The mapping follows the conventions popularised by mxj and goxml2json:
+// element children with unique names become object fields; repeated sibling
+// names collapse into a JSON array; attributes become fields prefixed with a
+// configurable string (default "-"); mixed chardata is placed under a
+// configurable text key (default "#text"); whitespace-only chardata is
+// dropped.
+//
+// Unlike those packages, xml2json does not build the whole document up as a
+// map[string]interface{} before marshalling it: it uses a small ordered
+// intermediate value instead, so field and attribute order is preserved, and
+// json.Marshal never has to round-trip the whole document through a single
+// []byte buffer. Grouping repeated siblings into a JSON array means the
+// cardinality of an element's children isn't known until all of them have
+// been read, so each element's subtree is still held in memory until it is
+// written. Convert reads the whole root element before writing any of it,
+// so the whole document is held in memory at once.
+package xml2json
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"regexp"
+)
+
+// numberPattern matches the grammar of a JSON number.
+var numberPattern = regexp.MustCompile(`^-?(?:0|[1-9]\d*)(?:\.\d+)?(?:[eE][+-]?\d+)?$`)
+
+// Converter represents the ongoing conversion from XML to JSON.
+type Converter struct {
+	// AttrPrefix is prepended to the name of an XML attribute when it is
+	// turned into a JSON object field. Defaults to "-".
+	AttrPrefix string
+
+	// TextKey names the JSON object field used for an element's chardata
+	// when the element also has attributes or child elements. Defaults to
+	// "#text".
+	TextKey string
+
+	// TypeCast detects numbers, booleans and null in chardata and attribute
+	// values and emits them as their JSON types instead of strings.
+	TypeCast bool
+
+	// ForceList names elements that should always be emitted as a JSON
+	// array, even when only one is present.
+	ForceList map[string]bool
+}
+
+// NewConverter provides a Converter with the default AttrPrefix and TextKey.
+func NewConverter() *Converter {
+	return &Converter{
+		AttrPrefix: "-",
+		TextKey:    "#text",
+	}
+}
+
+// Convert reads XML elements from x and writes the equivalent JSON to w.
+func (c *Converter) Convert(x xml.TokenReader, w io.Writer) error {
+	for {
+		tok, err := x.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		val, err := c.parseElement(x, start)
+		if err != nil {
+			return err
+		}
+
+		root := newOMap()
+		root.set(start.Name.Local, val, c.ForceList[start.Name.Local])
+
+		return c.writeValue(w, root)
+	}
+}
+
+// omap is a JSON object that remembers the order in which its keys were
+// first seen, so the generated JSON mirrors the order elements and
+// attributes appeared in the source XML.
+type omap struct {
+	keys []string
+	vals map[string]interface{}
+}
+
+func newOMap() *omap {
+	return &omap{vals: make(map[string]interface{})}
+}
+
+func (o *omap) set(key string, v interface{}, forceList bool) {
+	existing, ok := o.vals[key]
+	if !ok {
+		if forceList {
+			v = []interface{}{v}
+		}
+
+		o.keys = append(o.keys, key)
+		o.vals[key] = v
+
+		return
+	}
+
+	if arr, ok := existing.([]interface{}); ok {
+		o.vals[key] = append(arr, v)
+		return
+	}
+
+	o.vals[key] = []interface{}{existing, v}
+}
+
+// parseElement consumes tokens up to and including the matching EndElement
+// for start, and returns the JSON value it represents: nil for an empty
+// element with no attributes, a string for a leaf element with no
+// attributes, or an *omap otherwise.
+func (c *Converter) parseElement(x xml.TokenReader, start xml.StartElement) (interface{}, error) {
+	obj := newOMap()
+
+	for _, a := range start.Attr {
+		obj.set(c.AttrPrefix+a.Name.Local, a.Value, false)
+	}
+
+	var text bytes.Buffer
+
+	for {
+		tok, err := x.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			child, err := c.parseElement(x, t)
+			if err != nil {
+				return nil, err
+			}
+
+			obj.set(t.Name.Local, child, c.ForceList[t.Name.Local])
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			return c.finishElement(obj, bytes.TrimSpace(text.Bytes()))
+		}
+	}
+}
+
+func (c *Converter) finishElement(obj *omap, text []byte) (interface{}, error) {
+	if len(text) == 0 {
+		if len(obj.keys) == 0 {
+			return nil, nil
+		}
+
+		return obj, nil
+	}
+
+	if len(obj.keys) == 0 {
+		return string(text), nil
+	}
+
+	obj.set(c.TextKey, string(text), false)
+
+	return obj, nil
+}
+
+// writeValue writes v to w as JSON, recursing into *omap and []interface{}
+// values and writing each one directly to w rather than building a combined
+// []byte for the whole value first.
+func (c *Converter) writeValue(w io.Writer, v interface{}) error {
+	switch val := v.(type) {
+	case nil:
+		_, err := io.WriteString(w, "null")
+		return err
+	case *omap:
+		return c.writeObject(w, val)
+	case []interface{}:
+		return c.writeArray(w, val)
+	case string:
+		return c.writeString(w, val)
+	default:
+		enc := json.NewEncoder(w)
+		return enc.Encode(val)
+	}
+}
+
+func (c *Converter) writeObject(w io.Writer, obj *omap) error {
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+
+	for i, key := range obj.keys {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+
+		if err := writeJSONString(w, key); err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(w, ":"); err != nil {
+			return err
+		}
+
+		if err := c.writeValue(w, obj.vals[key]); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "}")
+
+	return err
+}
+
+func (c *Converter) writeArray(w io.Writer, arr []interface{}) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	for i, v := range arr {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+
+		if err := c.writeValue(w, v); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "]")
+
+	return err
+}
+
+// writeString writes s to w as a JSON string, unless TypeCast is enabled and
+// s looks like a number, boolean or null, in which case it is written as
+// that type instead.
+func (c *Converter) writeString(w io.Writer, s string) error {
+	if c.TypeCast {
+		switch {
+		case s == "null":
+			_, err := io.WriteString(w, "null")
+			return err
+		case s == "true" || s == "false":
+			_, err := io.WriteString(w, s)
+			return err
+		case numberPattern.MatchString(s):
+			_, err := io.WriteString(w, s)
+			return err
+		}
+	}
+
+	return writeJSONString(w, s)
+}
+
+// writeJSONString writes s to w as a quoted JSON string, with no TypeCast
+// interpretation. Object keys always go through this rather than
+// writeString, since a key is always a string regardless of TypeCast.
+func writeJSONString(w io.Writer, s string) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+
+	return err
+}
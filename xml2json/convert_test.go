@@ -0,0 +1,107 @@
+package xml2json
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+)
+
+func convert(t *testing.T, in string, configure func(*Converter)) string {
+	t.Helper()
+
+	c := NewConverter()
+	if configure != nil {
+		configure(c)
+	}
+
+	var buf bytes.Buffer
+	if err := c.Convert(xml.NewDecoder(bytes.NewBufferString(in)), &buf); err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	return buf.String()
+}
+
+func TestConvertFieldsAndAttrs(t *testing.T) {
+	got := convert(t, `<root id="1"><name>a</name></root>`, nil)
+	want := `{"root":{"-id":"1","name":"a"}}`
+
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestConvertRepeatedSiblingsCollapseIntoArray(t *testing.T) {
+	got := convert(t, `<root><item>a</item><item>b</item></root>`, nil)
+	want := `{"root":{"item":["a","b"]}}`
+
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestConvertForceList(t *testing.T) {
+	got := convert(t, `<root><item>a</item></root>`, func(c *Converter) {
+		c.ForceList = map[string]bool{"item": true}
+	})
+	want := `{"root":{"item":["a"]}}`
+
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestConvertMixedContentUsesTextKey(t *testing.T) {
+	got := convert(t, `<root id="1">hello</root>`, nil)
+	want := `{"root":{"-id":"1","#text":"hello"}}`
+
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestConvertWhitespaceOnlyCharDataDropped(t *testing.T) {
+	got := convert(t, "<root>\n  <item>a</item>\n</root>", nil)
+	want := `{"root":{"item":"a"}}`
+
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestConvertTypeCast(t *testing.T) {
+	got := convert(t, `<root><n>42</n><b>true</b><z>null</z></root>`, func(c *Converter) {
+		c.TypeCast = true
+	})
+	want := `{"root":{"n":42,"b":true,"z":null}}`
+
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestConvertEmptyElementIsNull(t *testing.T) {
+	got := convert(t, `<root></root>`, nil)
+	want := `{"root":null}`
+
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestConvertTypeCastDoesNotUnquoteKeys(t *testing.T) {
+	got := convert(t, `<root><true>hello</true><other>5</other></root>`, func(c *Converter) {
+		c.TypeCast = true
+	})
+	want := `{"root":{"true":"hello","other":5}}`
+
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(got), &v); err != nil {
+		t.Fatalf("json.Unmarshal(%s): %v", got, err)
+	}
+}